@@ -0,0 +1,255 @@
+package x
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rocketlaunchr/dbq/v2"
+)
+
+// Predicate is a composable WHERE-clause fragment. It is implemented by the
+// values returned from Eq, In, And, Or and Raw, and is consumed by
+// BulkUpdateQueryOptions.Where.
+//
+// Implementations are responsible for rendering themselves using the
+// placeholder style appropriate for dbType (? for MySQL, $N for PostgreSQL),
+// advancing phIdx as they consume PostgreSQL placeholders.
+type Predicate interface {
+	render(dbType dbq.Database, phIdx *int) (string, []interface{})
+}
+
+type eqPredicate struct {
+	column string
+	value  interface{}
+}
+
+func (p eqPredicate) render(dbType dbq.Database, phIdx *int) (string, []interface{}) {
+	return fmt.Sprintf("%s = %s", p.column, nextPh(dbType, phIdx)), []interface{}{p.value}
+}
+
+// Eq builds a "column = value" predicate.
+func Eq(column string, value interface{}) Predicate {
+	return eqPredicate{column, value}
+}
+
+type inPredicate struct {
+	column string
+	values []interface{}
+}
+
+func (p inPredicate) render(dbType dbq.Database, phIdx *int) (string, []interface{}) {
+	if len(p.values) == 0 {
+		// An empty IN() matches nothing, so render a predicate that is always false
+		// rather than emitting invalid SQL.
+		return "1 = 0", nil
+	}
+
+	group := dbq.Ph(len(p.values), 1, *phIdx, dbType)
+	if dbType == dbq.PostgreSQL {
+		*phIdx += len(p.values)
+	}
+
+	return fmt.Sprintf("%s IN %s", p.column, group), p.values
+}
+
+// In builds a "column IN (values...)" predicate.
+func In(column string, values ...interface{}) Predicate {
+	return inPredicate{column, values}
+}
+
+type andPredicate struct {
+	preds []Predicate
+}
+
+func (p andPredicate) render(dbType dbq.Database, phIdx *int) (string, []interface{}) {
+	return joinPredicates(p.preds, "AND", dbType, phIdx)
+}
+
+// And combines predicates with AND. An empty And is always true.
+func And(preds ...Predicate) Predicate {
+	return andPredicate{preds}
+}
+
+type orPredicate struct {
+	preds []Predicate
+}
+
+func (p orPredicate) render(dbType dbq.Database, phIdx *int) (string, []interface{}) {
+	return joinPredicates(p.preds, "OR", dbType, phIdx)
+}
+
+// Or combines predicates with OR. An empty Or is always true.
+func Or(preds ...Predicate) Predicate {
+	return orPredicate{preds}
+}
+
+func joinPredicates(preds []Predicate, joiner string, dbType dbq.Database, phIdx *int) (string, []interface{}) {
+	if len(preds) == 0 {
+		return "1 = 1", nil
+	}
+
+	parts := make([]string, 0, len(preds))
+	var args []interface{}
+
+	for _, pred := range preds {
+		s, a := pred.render(dbType, phIdx)
+		parts = append(parts, "("+s+")")
+		args = append(args, a...)
+	}
+
+	return strings.Join(parts, " "+joiner+" "), args
+}
+
+type rawPredicate struct {
+	sql  string
+	args []interface{}
+}
+
+func (p rawPredicate) render(dbType dbq.Database, phIdx *int) (string, []interface{}) {
+	return rewritePh(dbType, p.sql, phIdx), p.args
+}
+
+// Raw builds a predicate from a hand-written SQL fragment. Write placeholders
+// as "?"; for PostgreSQL they are rewritten to the correctly numbered $N form.
+func Raw(sql string, args ...interface{}) Predicate {
+	return rawPredicate{sql, args}
+}
+
+// nextPh returns the next placeholder for dbType, advancing phIdx for PostgreSQL's
+// numbered placeholders.
+func nextPh(dbType dbq.Database, phIdx *int) string {
+	if dbType == dbq.PostgreSQL {
+		*phIdx++
+		return fmt.Sprintf("$%d", *phIdx)
+	}
+	return "?"
+}
+
+// rewritePh rewrites every "?" placeholder in sql to its PostgreSQL $N form,
+// advancing phIdx as it goes. For MySQL, sql is returned unchanged.
+func rewritePh(dbType dbq.Database, sql string, phIdx *int) string {
+	if dbType != dbq.PostgreSQL || !strings.Contains(sql, "?") {
+		return sql
+	}
+
+	var b strings.Builder
+	for _, r := range sql {
+		if r == '?' {
+			*phIdx++
+			fmt.Fprintf(&b, "$%d", *phIdx)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// BulkUpdateQueryOptions is used to configure the BulkUpdateQuery function.
+type BulkUpdateQueryOptions struct {
+
+	// Table sets the table name.
+	Table string
+
+	// Set maps each column being updated to either a literal value or a dbq.Expr
+	// holding just the right-hand side of the assignment (e.g. dbq.Expr{Expr:
+	// "count + ?", Args: []interface{}{1}} for "count = count + ?") — BulkUpdateQuery
+	// itself renders the "column = " prefix, so Expr.Expr must not repeat it.
+	Set map[string]interface{}
+
+	// Where selects the rows to update. It is required; to update every row,
+	// pass x.Raw("1 = 1") explicitly.
+	Where Predicate
+
+	// StmtSuffix appends additional sql content to the end of the generated sql statement.
+	StmtSuffix string
+
+	// DBType sets the database being used. The default is MySQL.
+	DBType dbq.Database
+}
+
+// BulkUpdateQuery updates every row matched by opts.Where, unlike BulkUpdate
+// which requires the primary key value for each row up front.
+//
+// Example:
+//
+//  opts := x.BulkUpdateQueryOptions{
+//     Table: "tablename",
+//     Set: map[string]interface{}{
+//        "status": "archived",
+//        "count":  dbq.Expr{Expr: "count + ?", Args: []interface{}{1}},
+//     },
+//     Where: x.And(
+//        x.Raw("updated_at < ?", cutoff),
+//        x.In("tenant_id", 1, 2, 3),
+//     ),
+//  }
+//
+//  x.BulkUpdateQuery(ctx, db, opts)
+//
+func BulkUpdateQuery(ctx context.Context, db dbq.ExecContexter, opts BulkUpdateQueryOptions) (sql.Result, error) {
+
+	stmt, queryArgs, err := buildBulkUpdateQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbq.E(ctx, db, stmt, nil, queryArgs...)
+}
+
+// buildBulkUpdateQuery validates opts and renders the UPDATE statement and its
+// arguments. It is split out from BulkUpdateQuery so the SQL generation can be
+// tested without a real dbq.ExecContexter.
+func buildBulkUpdateQuery(opts BulkUpdateQueryOptions) (string, []interface{}, error) {
+
+	if opts.Table == "" {
+		return "", nil, errors.New("no table name provided")
+	}
+
+	if len(opts.Set) == 0 {
+		return "", nil, errors.New("no columns provided to update")
+	}
+
+	if opts.Where == nil {
+		return "", nil, errors.New("a Where predicate is required; use x.Raw(\"1 = 1\") to update every row")
+	}
+
+	queryArgs := []interface{}{}
+	var phIdx int
+
+	// Columns are sorted so the generated SQL (and its placeholder order) is deterministic.
+	cols := make([]string, 0, len(opts.Set))
+	for col := range opts.Set {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	setClauses := make([]string, 0, len(cols))
+
+	for _, col := range cols {
+		val := opts.Set[col]
+
+		if expr, ok := val.(dbq.Expr); ok {
+			setClauses = append(setClauses, fmt.Sprintf("%s = %s", col, rewritePh(opts.DBType, expr.Expr, &phIdx)))
+			queryArgs = append(queryArgs, expr.Args...)
+			continue
+		}
+
+		setClauses = append(setClauses, fmt.Sprintf("%s = %s", col, nextPh(opts.DBType, &phIdx)))
+		queryArgs = append(queryArgs, val)
+	}
+
+	whereSQL, whereArgs := opts.Where.render(opts.DBType, &phIdx)
+	queryArgs = append(queryArgs, whereArgs...)
+
+	stmt := fmt.Sprintf("UPDATE %s SET %s WHERE %s", opts.Table, strings.Join(setClauses, ", "), whereSQL)
+
+	if opts.StmtSuffix != "" {
+		stmt = stmt + " " + opts.StmtSuffix
+	}
+
+	return stmt, queryArgs, nil
+}
@@ -0,0 +1,194 @@
+package x
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rocketlaunchr/dbq/v2"
+)
+
+func TestEqRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   dbq.Database
+		startIdx int
+		wantSQL  string
+		wantIdx  int
+	}{
+		{"mysql", dbq.MySQL, 0, "age = ?", 0},
+		{"postgres", dbq.PostgreSQL, 2, "age = $3", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phIdx := tt.startIdx
+			sql, args := Eq("age", 5).render(tt.dbType, &phIdx)
+
+			if sql != tt.wantSQL {
+				t.Errorf("sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, []interface{}{5}) {
+				t.Errorf("args = %v, want [5]", args)
+			}
+			if phIdx != tt.wantIdx {
+				t.Errorf("phIdx = %d, want %d", phIdx, tt.wantIdx)
+			}
+		})
+	}
+}
+
+func TestInRenderEmpty(t *testing.T) {
+	phIdx := 0
+	sql, args := In("tenant_id").render(dbq.PostgreSQL, &phIdx)
+
+	if sql != "1 = 0" {
+		t.Errorf("sql = %q, want %q", sql, "1 = 0")
+	}
+	if args != nil {
+		t.Errorf("args = %v, want nil", args)
+	}
+	if phIdx != 0 {
+		t.Errorf("phIdx = %d, want unchanged 0", phIdx)
+	}
+}
+
+func TestInRenderPostgresAdvancesPhIdx(t *testing.T) {
+	phIdx := 1
+	sql, args := In("tenant_id", 1, 2, 3).render(dbq.PostgreSQL, &phIdx)
+
+	if !reflect.DeepEqual(args, []interface{}{1, 2, 3}) {
+		t.Errorf("args = %v, want [1 2 3]", args)
+	}
+	if phIdx != 4 {
+		t.Errorf("phIdx = %d, want 4", phIdx)
+	}
+	if sql == "" {
+		t.Errorf("sql was empty")
+	}
+}
+
+func TestAndOrCombinesWithParens(t *testing.T) {
+	phIdx := 0
+	sql, args := And(Eq("a", 1), Eq("b", 2)).render(dbq.MySQL, &phIdx)
+
+	want := "(a = ?) AND (b = ?)"
+	if sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 2}) {
+		t.Errorf("args = %v, want [1 2]", args)
+	}
+
+	phIdx = 0
+	sql, _ = Or(Eq("a", 1), Eq("b", 2)).render(dbq.MySQL, &phIdx)
+	want = "(a = ?) OR (b = ?)"
+	if sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+}
+
+func TestAndOrEmptyIsAlwaysTrue(t *testing.T) {
+	phIdx := 0
+	if sql, args := And().render(dbq.MySQL, &phIdx); sql != "1 = 1" || args != nil {
+		t.Errorf("And() = (%q, %v), want (\"1 = 1\", nil)", sql, args)
+	}
+	if sql, args := Or().render(dbq.MySQL, &phIdx); sql != "1 = 1" || args != nil {
+		t.Errorf("Or() = (%q, %v), want (\"1 = 1\", nil)", sql, args)
+	}
+}
+
+func TestRawRewritesPlaceholdersForPostgres(t *testing.T) {
+	phIdx := 2
+	sql, args := Raw("updated_at < ? and tenant_id = ?", "cutoff", 7).render(dbq.PostgreSQL, &phIdx)
+
+	want := "updated_at < $3 and tenant_id = $4"
+	if sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"cutoff", 7}) {
+		t.Errorf("args = %v, want [cutoff 7]", args)
+	}
+	if phIdx != 4 {
+		t.Errorf("phIdx = %d, want 4", phIdx)
+	}
+}
+
+func TestRawLeftUnchangedForMySQL(t *testing.T) {
+	phIdx := 0
+	sql, _ := Raw("updated_at < ?", "cutoff").render(dbq.MySQL, &phIdx)
+
+	if sql != "updated_at < ?" {
+		t.Errorf("sql = %q, want unchanged", sql)
+	}
+	if phIdx != 0 {
+		t.Errorf("phIdx = %d, want unchanged 0", phIdx)
+	}
+}
+
+func TestBuildBulkUpdateQueryWithExpr(t *testing.T) {
+	opts := BulkUpdateQueryOptions{
+		Table: "tablename",
+		Set: map[string]interface{}{
+			"count": dbq.Expr{Expr: "count + ?", Args: []interface{}{1}},
+		},
+		Where:  Eq("id", 5),
+		DBType: dbq.MySQL,
+	}
+
+	stmt, args, err := buildBulkUpdateQuery(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Expr.Expr is only the right-hand side; BulkUpdateQuery supplies "col =" itself.
+	// A caller mistakenly writing the full assignment (e.g. "count = count + ?")
+	// would produce "count = count = count + ?" here.
+	want := "UPDATE tablename SET count = count + ? WHERE id = ?"
+	if stmt != want {
+		t.Errorf("stmt = %q, want %q", stmt, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 5}) {
+		t.Errorf("args = %v, want [1 5]", args)
+	}
+}
+
+func TestBuildBulkUpdateQueryWithExprPostgres(t *testing.T) {
+	opts := BulkUpdateQueryOptions{
+		Table: "tablename",
+		Set: map[string]interface{}{
+			"count": dbq.Expr{Expr: "count + ?", Args: []interface{}{1}},
+		},
+		Where:  Eq("id", 5),
+		DBType: dbq.PostgreSQL,
+	}
+
+	stmt, args, err := buildBulkUpdateQuery(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "UPDATE tablename SET count = count + $1 WHERE id = $2"
+	if stmt != want {
+		t.Errorf("stmt = %q, want %q", stmt, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 5}) {
+		t.Errorf("args = %v, want [1 5]", args)
+	}
+}
+
+func TestBulkUpdateQueryValidation(t *testing.T) {
+	if _, err := BulkUpdateQuery(nil, nil, BulkUpdateQueryOptions{}); err == nil {
+		t.Error("expected error when Table is missing")
+	}
+
+	if _, err := BulkUpdateQuery(nil, nil, BulkUpdateQueryOptions{Table: "t"}); err == nil {
+		t.Error("expected error when Set is empty")
+	}
+
+	if _, err := BulkUpdateQuery(nil, nil, BulkUpdateQueryOptions{
+		Table: "t",
+		Set:   map[string]interface{}{"status": "archived"},
+	}); err == nil {
+		t.Error("expected error when Where is nil")
+	}
+}
@@ -0,0 +1,256 @@
+package x
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rocketlaunchr/dbq/v2"
+)
+
+// BulkUpsertOptions is used to configure the BulkUpsert function.
+type BulkUpsertOptions struct {
+
+	// Table sets the table name.
+	Table string
+
+	// Columns sets the columns being inserted, in the order each row's values appear.
+	Columns []string
+
+	// ConflictColumns identifies the unique/primary key columns that trigger the
+	// update branch. For MySQL these only need to match an existing unique index;
+	// for PostgreSQL they are rendered into ON CONFLICT (...).
+	ConflictColumns []string
+
+	// UpdateColumns sets which columns are updated when a row conflicts. If empty,
+	// it defaults to every column in Columns that isn't in ConflictColumns.
+	UpdateColumns []string
+
+	// Where sets an optional WHERE clause for PostgreSQL's "DO UPDATE SET ... WHERE".
+	// It is ignored for MySQL, which has no equivalent.
+	Where Predicate
+
+	// StmtSuffix appends additional sql content to the end of the generated sql statement.
+	StmtSuffix string
+
+	// DBType sets the database being used. The default is MySQL.
+	DBType dbq.Database
+
+	// BatchSize caps the number of rows inserted by a single generated statement.
+	// If zero, BulkUpsert picks the largest batch that stays within MaxPlaceholders.
+	BatchSize int
+
+	// MaxPlaceholders caps the number of placeholders used in a single generated
+	// statement. If zero, it defaults to 65535 for PostgreSQL and is left
+	// unbounded for MySQL.
+	MaxPlaceholders int
+
+	// Logger, if set, is called with each generated statement and its arguments.
+	Logger func(stmt string, args []interface{})
+}
+
+// BulkUpsert inserts rows, updating the matching row in place wherever Columns
+// collides with an existing row on ConflictColumns. It generates a single
+// multi-row "INSERT ... ON DUPLICATE KEY UPDATE" for MySQL or
+// "INSERT ... ON CONFLICT (...) DO UPDATE SET" for PostgreSQL, chunked so it
+// stays within opts.BatchSize and opts.MaxPlaceholders.
+//
+// Each entry in rows must have the same length as opts.Columns, in the same order.
+//
+// Example:
+//
+//  opts := x.BulkUpsertOptions{
+//     Table:           "tablename",
+//     Columns:         []string{"id", "name", "age"},
+//     ConflictColumns: []string{"id"},
+//  }
+//
+//  rows := [][]interface{}{
+//     {1, "rabbit", 5},
+//     {2, "cat", 8},
+//  }
+//
+//  x.BulkUpsert(ctx, db, rows, opts)
+//
+func BulkUpsert(ctx context.Context, db dbq.ExecContexter, rows [][]interface{}, opts BulkUpsertOptions) (sql.Result, error) {
+
+	if opts.Table == "" || len(opts.Columns) == 0 {
+		return nil, errors.New("no table name or column name(s) provided")
+	}
+
+	if len(opts.ConflictColumns) == 0 {
+		return nil, errors.New("conflict column(s) need to be specified")
+	}
+
+	if len(rows) == 0 {
+		return &res{}, nil
+	}
+
+	for _, row := range rows {
+		if len(row) != len(opts.Columns) {
+			return nil, errors.New("each row must have the same number of values as there are columns")
+		}
+	}
+
+	updateColumns := opts.UpdateColumns
+	if len(updateColumns) == 0 {
+		conflict := make(map[string]bool, len(opts.ConflictColumns))
+		for _, col := range opts.ConflictColumns {
+			conflict[col] = true
+		}
+		for _, col := range opts.Columns {
+			if !conflict[col] {
+				updateColumns = append(updateColumns, col)
+			}
+		}
+	}
+
+	var wherePlaceholders int
+	if opts.DBType == dbq.PostgreSQL && len(updateColumns) > 0 && opts.Where != nil {
+		_, whereArgs := opts.Where.render(opts.DBType, new(int))
+		wherePlaceholders = len(whereArgs)
+	}
+
+	batchSize, err := upsertBatchSizeFor(len(rows), wherePlaceholders, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	total := &aggregateResult{}
+
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		result, err := bulkUpsertBatch(ctx, db, rows[start:end], updateColumns, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		total.rowsAffected += affected
+	}
+
+	return total, nil
+}
+
+// upsertBatchSizeFor determines how many rows each generated statement should
+// cover, honoring opts.BatchSize and capping it so opts.MaxPlaceholders (or its
+// default for PostgreSQL) is never exceeded. reservedPlaceholders accounts for
+// placeholders consumed outside the VALUES list (e.g. opts.Where) so a batch's
+// total placeholder count, not just its VALUES placeholders, stays within the limit.
+//
+// It errors rather than silently falling back to an unbounded batch when even a
+// single row can't fit within the placeholder budget alongside reservedPlaceholders.
+func upsertBatchSizeFor(rows, reservedPlaceholders int, opts BulkUpsertOptions) (int, error) {
+
+	maxPlaceholders := opts.MaxPlaceholders
+	if maxPlaceholders == 0 && opts.DBType == dbq.PostgreSQL {
+		maxPlaceholders = defaultMaxPlaceholders
+	}
+
+	batchSize := opts.BatchSize
+
+	if maxPlaceholders > 0 {
+		placeholdersPerRow := len(opts.Columns)
+
+		fit := (maxPlaceholders - reservedPlaceholders) / placeholdersPerRow
+		if fit <= 0 {
+			return 0, fmt.Errorf("MaxPlaceholders (%d) cannot fit even a single row (%d placeholders) alongside %d reserved by Where", maxPlaceholders, placeholdersPerRow, reservedPlaceholders)
+		}
+
+		if batchSize == 0 || fit < batchSize {
+			batchSize = fit
+		}
+	}
+
+	if batchSize <= 0 || batchSize > rows {
+		batchSize = rows
+	}
+
+	return batchSize, nil
+}
+
+// bulkUpsertBatch generates and executes a single multi-row upsert statement
+// covering rows.
+func bulkUpsertBatch(ctx context.Context, db dbq.ExecContexter, rows [][]interface{}, updateColumns []string, opts BulkUpsertOptions) (sql.Result, error) {
+
+	queryArgs := []interface{}{}
+
+	var phIdx int
+
+	valueGroups := make([]string, 0, len(rows))
+	for _, row := range rows {
+		placeholders := make([]string, len(row))
+		for i := range row {
+			placeholders[i] = nextPh(opts.DBType, &phIdx)
+		}
+		valueGroups = append(valueGroups, "("+strings.Join(placeholders, ", ")+")")
+		queryArgs = append(queryArgs, row...)
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (%s)\nVALUES\n%s\n",
+		opts.Table,
+		strings.Join(opts.Columns, ", "),
+		strings.Join(valueGroups, ",\n"))
+
+	if opts.DBType == dbq.PostgreSQL {
+		stmt = stmt + fmt.Sprintf("ON CONFLICT (%s) DO %s", strings.Join(opts.ConflictColumns, ", "), pgUpdateClause(updateColumns))
+
+		if len(updateColumns) > 0 && opts.Where != nil {
+			whereSQL, whereArgs := opts.Where.render(opts.DBType, &phIdx)
+			stmt = stmt + " WHERE " + whereSQL
+			queryArgs = append(queryArgs, whereArgs...)
+		}
+	} else {
+		stmt = stmt + fmt.Sprintf("ON DUPLICATE KEY UPDATE %s", mysqlUpdateClause(updateColumns, opts.ConflictColumns))
+	}
+
+	if opts.StmtSuffix != "" {
+		stmt = stmt + " " + opts.StmtSuffix
+	}
+
+	if opts.Logger != nil {
+		opts.Logger(stmt, queryArgs)
+	}
+
+	return dbq.E(ctx, db, stmt, nil, queryArgs...)
+}
+
+// pgUpdateClause renders the "UPDATE SET ..." (or "NOTHING") half of a
+// PostgreSQL ON CONFLICT clause.
+func pgUpdateClause(updateColumns []string) string {
+	if len(updateColumns) == 0 {
+		return "NOTHING"
+	}
+
+	assignments := make([]string, len(updateColumns))
+	for i, col := range updateColumns {
+		assignments[i] = fmt.Sprintf("%s = EXCLUDED.%s", col, col)
+	}
+
+	return "UPDATE SET " + strings.Join(assignments, ", ")
+}
+
+// mysqlUpdateClause renders the assignment list for ON DUPLICATE KEY UPDATE.
+// If there are no columns to update, it assigns the first conflict column to
+// itself so the statement stays valid SQL.
+func mysqlUpdateClause(updateColumns, conflictColumns []string) string {
+	if len(updateColumns) == 0 {
+		return fmt.Sprintf("%s = %s", conflictColumns[0], conflictColumns[0])
+	}
+
+	assignments := make([]string, len(updateColumns))
+	for i, col := range updateColumns {
+		assignments[i] = fmt.Sprintf("%s = VALUES(%s)", col, col)
+	}
+
+	return strings.Join(assignments, ", ")
+}
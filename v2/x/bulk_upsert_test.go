@@ -0,0 +1,105 @@
+package x
+
+import (
+	"testing"
+
+	"github.com/rocketlaunchr/dbq/v2"
+)
+
+func TestUpsertBatchSizeForNoLimitsUsesAllRows(t *testing.T) {
+	got, err := upsertBatchSizeFor(10, 0, BulkUpsertOptions{Columns: []string{"a", "b"}, DBType: dbq.MySQL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 10 {
+		t.Errorf("got %d, want 10", got)
+	}
+}
+
+func TestUpsertBatchSizeForPostgresReservesWherePlaceholders(t *testing.T) {
+	opts := BulkUpsertOptions{Columns: []string{"a", "b", "c"}, DBType: dbq.PostgreSQL}
+	// 3 columns per row, cap 65535, 100 placeholders reserved by Where => (65535-100)/3.
+	got, err := upsertBatchSizeFor(1000000, 100, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := (defaultMaxPlaceholders - 100) / 3
+	if got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
+
+func TestUpsertBatchSizeForErrorsWhenBudgetCannotFitOneRow(t *testing.T) {
+	// Reproduces the review report: reserved placeholders leave less than one
+	// row's worth of room, so upsertBatchSizeFor must error rather than fall
+	// through to "no chunking at all".
+	tests := []struct {
+		name     string
+		columns  []string
+		reserved int
+	}{
+		{"reserved leaves negative room", []string{"a", "b", "c"}, 65540},
+		{"exact-zero integer division", make([]string, 10), 65530},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := BulkUpsertOptions{Columns: tt.columns, DBType: dbq.PostgreSQL}
+			batchSize, err := upsertBatchSizeFor(100000, tt.reserved, opts)
+			if err == nil {
+				t.Fatalf("expected an error, got batchSize=%d", batchSize)
+			}
+		})
+	}
+}
+
+func TestUpsertBatchSizeForExplicitBatchSizeStillClamped(t *testing.T) {
+	opts := BulkUpsertOptions{Columns: []string{"a"}, DBType: dbq.PostgreSQL, BatchSize: 100, MaxPlaceholders: 10}
+	got, err := upsertBatchSizeFor(1000, 0, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 10 {
+		t.Errorf("got %d, want 10 (MaxPlaceholders should win over the larger BatchSize)", got)
+	}
+}
+
+func TestPgUpdateClause(t *testing.T) {
+	if got := pgUpdateClause(nil); got != "NOTHING" {
+		t.Errorf("got %q, want NOTHING", got)
+	}
+
+	want := "UPDATE SET name = EXCLUDED.name, age = EXCLUDED.age"
+	if got := pgUpdateClause([]string{"name", "age"}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMysqlUpdateClause(t *testing.T) {
+	if got := mysqlUpdateClause(nil, []string{"id"}); got != "id = id" {
+		t.Errorf("got %q, want %q", got, "id = id")
+	}
+
+	want := "name = VALUES(name), age = VALUES(age)"
+	if got := mysqlUpdateClause([]string{"name", "age"}, []string{"id"}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBulkUpsertValidation(t *testing.T) {
+	if _, err := BulkUpsert(nil, nil, nil, BulkUpsertOptions{}); err == nil {
+		t.Error("expected error when Table/Columns are missing")
+	}
+
+	if _, err := BulkUpsert(nil, nil, nil, BulkUpsertOptions{Table: "t", Columns: []string{"id"}}); err == nil {
+		t.Error("expected error when ConflictColumns is empty")
+	}
+
+	if _, err := BulkUpsert(nil, nil, [][]interface{}{{1, 2}}, BulkUpsertOptions{
+		Table:           "t",
+		Columns:         []string{"id"},
+		ConflictColumns: []string{"id"},
+	}); err == nil {
+		t.Error("expected error when a row's length doesn't match Columns")
+	}
+}
@@ -0,0 +1,76 @@
+package x
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+// ColumnTypeResolver returns the PostgreSQL cast (e.g. "UUID", "JSONB", "TEXT[]")
+// to apply to col's value in a generated CASE statement. sample is one of the
+// values BulkUpdate is about to write to col; it may be nil.
+//
+// BulkUpdate calls the resolver once per value. To cast a column the same way
+// regardless of the value being written, prefer BulkUpdateOptions.ColumnTypes.
+type ColumnTypeResolver func(col string, sample interface{}) string
+
+// resolveColumnType determines the PostgreSQL cast for col's value, consulting
+// opts.ColumnTypes and opts.TypeResolver before falling back to defaultColumnType.
+func resolveColumnType(col string, v interface{}, opts BulkUpdateOptions) string {
+	if t, ok := opts.ColumnTypes[col]; ok {
+		return t
+	}
+
+	if opts.TypeResolver != nil {
+		return opts.TypeResolver(col, v)
+	}
+
+	return defaultColumnType(v)
+}
+
+// defaultColumnType is the built-in ColumnTypeResolver used when
+// BulkUpdateOptions.TypeResolver is unset. It recognises the Go basic types,
+// civil.Date/Time/DateTime, time.Time and driver.Valuer implementers, and
+// otherwise falls back to TEXT.
+func defaultColumnType(v interface{}) string {
+	if v == nil {
+		// Reached directly for a nil pointer, or via the driver.Valuer branch below
+		// for an unset sql.NullXxx. Either way there's no type information to go on,
+		// so fall back to TEXT rather than leaving the cast empty (an empty cast
+		// produces invalid SQL like "THEN $6::").
+		return "TEXT"
+	}
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err == nil {
+			return defaultColumnType(value)
+		}
+	}
+
+	switch v.(type) {
+	case uint, int, *uint, *int:
+		return "INT"
+	case uint8, uint16, uint32, uint64, *uint8, *uint16, *uint32, *uint64:
+		return "INT"
+	case int8, int16, int32, int64, *int8, *int16, *int32, *int64:
+		return "INT"
+	case string, *string:
+		return "VARCHAR"
+	case float32, *float32, float64, *float64:
+		return "NUMERIC"
+	case bool, *bool:
+		return "BOOLEAN"
+	case civil.Date, *civil.Date:
+		return "DATE"
+	case civil.DateTime, *civil.DateTime:
+		return "TIMESTAMP"
+	case civil.Time, *civil.Time:
+		return "TIME"
+	case time.Time, *time.Time:
+		return "TIMESTAMP"
+	default:
+		return "TEXT"
+	}
+}
@@ -0,0 +1,90 @@
+package x
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+func TestDefaultColumnTypeBasicKinds(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"nil", nil, "TEXT"},
+		{"int", 5, "INT"},
+		{"uint pointer", func() *uint { v := uint(1); return &v }(), "INT"},
+		{"string", "hi", "VARCHAR"},
+		{"float64", 1.5, "NUMERIC"},
+		{"bool", true, "BOOLEAN"},
+		{"civil.Date", civil.Date{Year: 2020, Month: 1, Day: 1}, "DATE"},
+		{"civil.DateTime", civil.DateTime{}, "TIMESTAMP"},
+		{"civil.Time", civil.Time{}, "TIME"},
+		{"time.Time", time.Now(), "TIMESTAMP"},
+		{"unrecognized struct", struct{}{}, "TEXT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultColumnType(tt.v); got != tt.want {
+				t.Errorf("defaultColumnType(%v) = %q, want %q", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultColumnTypeUnsetNullXxxFallsBackToText(t *testing.T) {
+	// sql.NullXxx types implement driver.Valuer and return (nil, nil) from
+	// Value() when Valid is false. This must not produce an empty cast type.
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"sql.NullString", sql.NullString{Valid: false}},
+		{"sql.NullInt64", sql.NullInt64{Valid: false}},
+		{"sql.NullBool", sql.NullBool{Valid: false}},
+		{"sql.NullFloat64", sql.NullFloat64{Valid: false}},
+		{"sql.NullTime", sql.NullTime{Valid: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultColumnType(tt.v); got != "TEXT" {
+				t.Errorf("defaultColumnType(%v) = %q, want %q", tt.v, got, "TEXT")
+			}
+		})
+	}
+}
+
+func TestDefaultColumnTypeValidNullXxxUsesUnderlyingValue(t *testing.T) {
+	if got := defaultColumnType(sql.NullString{String: "hi", Valid: true}); got != "VARCHAR" {
+		t.Errorf("got %q, want VARCHAR", got)
+	}
+	if got := defaultColumnType(sql.NullInt64{Int64: 5, Valid: true}); got != "INT" {
+		t.Errorf("got %q, want INT", got)
+	}
+}
+
+func TestResolveColumnTypePrecedence(t *testing.T) {
+	opts := BulkUpdateOptions{
+		ColumnTypes: map[string]string{"tags": "TEXT[]"},
+		TypeResolver: func(col string, sample interface{}) string {
+			return "CUSTOM"
+		},
+	}
+
+	if got := resolveColumnType("tags", "anything", opts); got != "TEXT[]" {
+		t.Errorf("ColumnTypes should win, got %q", got)
+	}
+
+	if got := resolveColumnType("other", "anything", opts); got != "CUSTOM" {
+		t.Errorf("TypeResolver should be used when ColumnTypes has no entry, got %q", got)
+	}
+
+	if got := resolveColumnType("other", 5, BulkUpdateOptions{}); got != "INT" {
+		t.Errorf("defaultColumnType should be used when neither override is set, got %q", got)
+	}
+}
@@ -7,9 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"time"
 
-	"cloud.google.com/go/civil"
 	"github.com/rocketlaunchr/dbq/v2"
 )
 
@@ -23,6 +21,32 @@ func (*res) RowsAffected() (int64, error) {
 	return 0, nil
 }
 
+// aggregateResult sums the RowsAffected of every batch a chunked BulkUpdate executes.
+type aggregateResult struct {
+	rowsAffected int64
+}
+
+func (r *aggregateResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (r *aggregateResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// defaultMaxPlaceholders is PostgreSQL's hard limit on the number of parameters
+// in a single statement. MySQL has no such limit, so it is only applied when
+// opts.DBType is dbq.PostgreSQL and opts.MaxPlaceholders is left at zero.
+const defaultMaxPlaceholders = 65535
+
+// BeginTxer is implemented by database handles capable of starting a
+// transaction, such as *sql.DB. BulkUpdate uses it to open a transaction of
+// its own when BulkUpdateOptions.Transactional is set and db isn't already a
+// transaction.
+type BeginTxer interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
 // BulkUpdateOptions is used to configure the BulkUpdate function.
 type BulkUpdateOptions struct {
 
@@ -41,6 +65,33 @@ type BulkUpdateOptions struct {
 
 	// DBType sets the database being used. The default is MySQL.
 	DBType dbq.Database
+
+	// BatchSize caps the number of rows updated by a single generated statement.
+	// If zero, BulkUpdate picks the largest batch that stays within MaxPlaceholders.
+	BatchSize int
+
+	// MaxPlaceholders caps the number of placeholders used in a single generated
+	// statement. If zero, it defaults to 65535 for PostgreSQL and is left
+	// unbounded for MySQL.
+	MaxPlaceholders int
+
+	// Transactional opens a transaction via BeginTxer and runs every batch inside
+	// it when db is not already a *sql.Tx. It is ignored when db is already a
+	// transaction, since all of its batches already share one.
+	Transactional bool
+
+	// ColumnTypes explicitly sets the PostgreSQL cast used for a column
+	// (e.g. {"tags": "TEXT[]", "meta": "JSONB", "id": "UUID"}), taking
+	// precedence over TypeResolver and the built-in default.
+	ColumnTypes map[string]string
+
+	// TypeResolver overrides the built-in PostgreSQL cast inference for columns
+	// not listed in ColumnTypes. See ColumnTypeResolver.
+	TypeResolver ColumnTypeResolver
+
+	// Logger, if set, is called with each generated statement and its arguments.
+	// Nothing is logged when it is unset.
+	Logger func(stmt string, args []interface{})
 }
 
 // BulkUpdate is used to update multiple rows in a table without a transaction.
@@ -50,6 +101,12 @@ type BulkUpdateOptions struct {
 // updateData's value is a slice containing the new values for each column. A nil value is acceptable.
 // The slice must be the same length as the number of columns being updated.
 //
+// For large updateData maps, BulkUpdate splits the work across multiple generated
+// statements so it stays within opts.BatchSize and opts.MaxPlaceholders (MySQL's
+// max_allowed_packet and PostgreSQL's 65535-parameter limit otherwise turn into a
+// single oversized statement). Set opts.Transactional to run every batch in one
+// transaction; if db isn't already a *sql.Tx, it must implement x.BeginTxer.
+//
 // NOTE: You should perform benchmarks to determine if using a transactions and multiple single-row updates is more efficient.
 //
 // Example:
@@ -81,24 +138,124 @@ func BulkUpdate(ctx context.Context, db dbq.ExecContexter, updateData map[interf
 		return nil, errors.New("primary key column in database table needs to be specified")
 	}
 
+	keys := make([]interface{}, 0, len(updateData))
+	for k := range updateData {
+		keys = append(keys, k)
+	}
+
+	batchSize, err := batchSizeFor(len(keys), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	execer := db
+	var tx *sql.Tx
+
+	if opts.Transactional {
+		if _, alreadyTx := db.(*sql.Tx); !alreadyTx {
+			beginner, ok := db.(BeginTxer)
+			if !ok {
+				return nil, errors.New("db does not implement x.BeginTxer, required when BulkUpdateOptions.Transactional is set")
+			}
+
+			var err error
+			tx, err = beginner.BeginTx(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			execer = tx
+		}
+	}
+
+	total := &aggregateResult{}
+
+	for start := 0; start < len(keys); start += batchSize {
+		end := start + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		result, err := bulkUpdateBatch(ctx, execer, keys[start:end], updateData, opts)
+		if err != nil {
+			if tx != nil {
+				tx.Rollback()
+			}
+			return nil, err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			if tx != nil {
+				tx.Rollback()
+			}
+			return nil, err
+		}
+		total.rowsAffected += rows
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+
+	return total, nil
+}
+
+// batchSizeFor determines how many rows each generated statement should cover,
+// honoring opts.BatchSize and capping it so opts.MaxPlaceholders (or its default
+// for PostgreSQL) is never exceeded.
+//
+// It errors rather than silently falling back to an unbatched statement when
+// even a single row can't fit within the placeholder budget.
+func batchSizeFor(rows int, opts BulkUpdateOptions) (int, error) {
+
+	maxPlaceholders := opts.MaxPlaceholders
+	if maxPlaceholders == 0 && opts.DBType == dbq.PostgreSQL {
+		maxPlaceholders = defaultMaxPlaceholders
+	}
+
+	batchSize := opts.BatchSize
+
+	if maxPlaceholders > 0 {
+		// Worst case: every column changes (no NULLs), plus one placeholder per
+		// row for the final WHERE ... IN clause.
+		placeholdersPerRow := len(opts.Columns)*2 + 1
+
+		fit := maxPlaceholders / placeholdersPerRow
+		if fit <= 0 {
+			return 0, fmt.Errorf("MaxPlaceholders (%d) cannot fit even a single row (%d placeholders)", maxPlaceholders, placeholdersPerRow)
+		}
+
+		if batchSize == 0 || fit < batchSize {
+			batchSize = fit
+		}
+	}
+
+	if batchSize <= 0 || batchSize > rows {
+		batchSize = rows
+	}
+
+	return batchSize, nil
+}
+
+// bulkUpdateBatch generates and executes a single UPDATE ... CASE statement
+// covering the rows identified by keys.
+func bulkUpdateBatch(ctx context.Context, db dbq.ExecContexter, keys []interface{}, updateData map[interface{}][]interface{}, opts BulkUpdateOptions) (sql.Result, error) {
+
 	queryArgs := []interface{}{}
 
 	sqlUpdate := fmt.Sprintf("UPDATE %s SET\n", opts.Table)
 	sqlUpdateBack := "\nWHERE " + opts.PrimaryKey + " IN %s"
 
-	// Generate query
-	var primaryKeys []interface{} // for final WHERE IN
-
 	var phIdx int
 
 	for j, field := range opts.Columns {
 
 		eachSet := fmt.Sprintf("%s = CASE\n", field)
 
-		for primaryKey, val := range updateData {
-			if j == 0 {
-				primaryKeys = append(primaryKeys, primaryKey)
-			}
+		for _, primaryKey := range keys {
+			val := updateData[primaryKey]
 
 			if val[j] == nil {
 				if opts.DBType == dbq.PostgreSQL {
@@ -125,33 +282,7 @@ func BulkUpdate(ctx context.Context, db dbq.ExecContexter, updateData map[interf
 
 				if opts.DBType == dbq.PostgreSQL {
 
-					var colType string
-					if v != nil {
-						switch v.(type) {
-						case uint, int, *uint, *int:
-							colType = "INT"
-						case uint8, uint16, uint32, uint64, *uint8, *uint16, *uint32, *uint64:
-							colType = "INT"
-						case int8, int16, int32, int64, *int8, *int16, *int32, *int64:
-							colType = "INT"
-						case string, *string:
-							colType = "VARCHAR"
-						case float32, *float32, float64, *float64:
-							colType = "NUMERIC"
-						case bool, *bool:
-							colType = "BOOLEAN"
-						case civil.Date, *civil.Date:
-							// FIX UP
-						case civil.DateTime, *civil.DateTime:
-							// FIX UP
-						case civil.Time, *civil.Time:
-							// FIX UP
-						case time.Time, *time.Time:
-							colType = "TIMESTAMP"
-						default:
-							colType = "TEXT"
-						}
-					}
+					colType := resolveColumnType(field, v, opts)
 
 					eachSet = eachSet + fmt.Sprintf("WHEN %v = $%d THEN $%d::%s\n", opts.PrimaryKey, phIdx+1, phIdx+2, colType)
 					phIdx += 2
@@ -168,16 +299,18 @@ func BulkUpdate(ctx context.Context, db dbq.ExecContexter, updateData map[interf
 	}
 	sqlUpdate = strings.TrimSuffix(sqlUpdate, ",\n")
 
-	stmt := sqlUpdate + fmt.Sprintf(sqlUpdateBack, dbq.Ph(len(primaryKeys), 1, phIdx, opts.DBType))
+	stmt := sqlUpdate + fmt.Sprintf(sqlUpdateBack, dbq.Ph(len(keys), 1, phIdx, opts.DBType))
 
 	// Add suffix
 	if opts.StmtSuffix != "" {
 		stmt = stmt + " " + opts.StmtSuffix
 	}
 
-	fmt.Println(stmt)
+	queryArgs = append(queryArgs, keys...)
 
-	queryArgs = append(queryArgs, primaryKeys...)
+	if opts.Logger != nil {
+		opts.Logger(stmt, queryArgs)
+	}
 
 	return dbq.E(ctx, db, stmt, nil, queryArgs...)
 }
@@ -0,0 +1,100 @@
+package x
+
+import (
+	"testing"
+
+	"github.com/rocketlaunchr/dbq/v2"
+)
+
+func TestBatchSizeForNoLimitsUsesAllRows(t *testing.T) {
+	got, err := batchSizeFor(10, BulkUpdateOptions{Columns: []string{"a", "b"}, DBType: dbq.MySQL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 10 {
+		t.Errorf("got %d, want 10", got)
+	}
+}
+
+func TestBatchSizeForExplicitBatchSize(t *testing.T) {
+	got, err := batchSizeFor(10, BulkUpdateOptions{Columns: []string{"a", "b"}, DBType: dbq.MySQL, BatchSize: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 3 {
+		t.Errorf("got %d, want 3", got)
+	}
+}
+
+func TestBatchSizeForExplicitBatchSizeLargerThanRows(t *testing.T) {
+	got, err := batchSizeFor(2, BulkUpdateOptions{Columns: []string{"a", "b"}, DBType: dbq.MySQL, BatchSize: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("got %d, want 2 (clamped to available rows)", got)
+	}
+}
+
+func TestBatchSizeForPostgresDefaultMaxPlaceholders(t *testing.T) {
+	// 2 columns => 2*2+1 = 5 placeholders per row. 65535 / 5 = 13107.
+	opts := BulkUpdateOptions{Columns: []string{"a", "b"}, DBType: dbq.PostgreSQL}
+	got, err := batchSizeFor(1000000, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := defaultMaxPlaceholders / (len(opts.Columns)*2 + 1)
+	if got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
+
+func TestBatchSizeForMaxPlaceholdersOverridesLargerBatchSize(t *testing.T) {
+	opts := BulkUpdateOptions{Columns: []string{"a"}, DBType: dbq.PostgreSQL, BatchSize: 1000, MaxPlaceholders: 30}
+	// 1 column => 1*2+1 = 3 placeholders per row. 30 / 3 = 10, smaller than BatchSize.
+	got, err := batchSizeFor(1000, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 10 {
+		t.Errorf("got %d, want 10", got)
+	}
+}
+
+func TestBatchSizeForMaxPlaceholdersSmallerBatchSizeWins(t *testing.T) {
+	opts := BulkUpdateOptions{Columns: []string{"a"}, DBType: dbq.PostgreSQL, BatchSize: 2, MaxPlaceholders: 3000}
+	got, err := batchSizeFor(1000, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("got %d, want 2", got)
+	}
+}
+
+func TestBatchSizeForMySQLHasNoImplicitCap(t *testing.T) {
+	opts := BulkUpdateOptions{Columns: []string{"a", "b", "c"}, DBType: dbq.MySQL}
+	got, err := batchSizeFor(1000000, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 1000000 {
+		t.Errorf("got %d, want 1000000 (MySQL has no default MaxPlaceholders)", got)
+	}
+}
+
+func TestBatchSizeForErrorsWhenBudgetCannotFitOneRow(t *testing.T) {
+	// Reproduces the review report: 60 columns => 60*2+1 = 121 placeholders per
+	// row, but MaxPlaceholders of 100 can't fit even one, so batchSizeFor must
+	// error instead of falling through to "no chunking at all".
+	columns := make([]string, 60)
+	for i := range columns {
+		columns[i] = "c"
+	}
+
+	opts := BulkUpdateOptions{Columns: columns, DBType: dbq.PostgreSQL, MaxPlaceholders: 100}
+	batchSize, err := batchSizeFor(100000, opts)
+	if err == nil {
+		t.Fatalf("expected an error, got batchSize=%d", batchSize)
+	}
+}